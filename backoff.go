@@ -0,0 +1,58 @@
+// Developed by Kaiser925 on 2021/2/2.
+// Lasted modified 2021/2/2.
+// Copyright (c) 2021.  All rights reserved
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requests4go
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff describes an exponential backoff strategy with jitter, shared by
+// ResumableUpload and the retry middleware.
+type Backoff struct {
+	// Base is the delay before the first retry.
+	Base time.Duration
+
+	// Factor is multiplied into the delay after every attempt.
+	Factor float64
+
+	// Cap is the largest delay Delay will ever return.
+	Cap time.Duration
+
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+}
+
+// DefaultBackoff returns the Backoff used when none is supplied: base 1s,
+// factor 2, capped at 32s, up to 7 attempts.
+func DefaultBackoff() Backoff {
+	return Backoff{
+		Base:        time.Second,
+		Factor:      2,
+		Cap:         32 * time.Second,
+		MaxAttempts: 7,
+	}
+}
+
+// Delay returns the delay to wait before attempt n, where attempt is
+// 0-indexed and counts retries, not the initial try. Half of the computed
+// delay is jittered so concurrent callers don't retry in lockstep.
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := float64(b.Base) * math.Pow(b.Factor, float64(attempt))
+	if c := float64(b.Cap); d > c {
+		d = c
+	}
+	return time.Duration(d/2 + rand.Float64()*d/2)
+}
@@ -0,0 +1,198 @@
+// Developed by Kaiser925 on 2021/2/2.
+// Lasted modified 2021/2/2.
+// Copyright (c) 2021.  All rights reserved
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requests4go
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Hash identifies a response body by digest algorithm and hex-encoded
+// value, e.g. {"sha256", "e3b0c4..."}.
+type Hash struct {
+	Algorithm string
+	Hex       string
+}
+
+// String returns h in "algorithm:hex" form, the form Verify expects.
+func (h Hash) String() string {
+	return h.Algorithm + ":" + h.Hex
+}
+
+// Digest returns the sha256 digest of r's decoded body, buffering the body
+// into memory if it hasn't been read yet.
+func (r *Response) Digest() (Hash, error) {
+	content, err := r.loadContent()
+	if err != nil {
+		return Hash{}, err
+	}
+	sum := sha256.Sum256(content)
+	return Hash{Algorithm: "sha256", Hex: hex.EncodeToString(sum[:])}, nil
+}
+
+// ErrDigestMismatch is returned by Verify when a response body doesn't
+// match the expected digest.
+var ErrDigestMismatch = errors.New("requests4go: digest mismatch")
+
+// Verify checks the sha256 digest of r's decoded body against expected,
+// which must be in "sha256:hex" form (the form Hash.String returns),
+// returning ErrDigestMismatch if it doesn't match. It's useful for
+// asserting the integrity of a downloaded release artifact in one call.
+func (r *Response) Verify(expected string) error {
+	algo, sum, ok := strings.Cut(expected, ":")
+	if !ok || algo != "sha256" {
+		return fmt.Errorf("requests4go: unsupported digest %q", expected)
+	}
+	digest, err := r.Digest()
+	if err != nil {
+		return err
+	}
+	if digest.Hex != sum {
+		return ErrDigestMismatch
+	}
+	return nil
+}
+
+// Cache is an on-disk, content-addressable response cache. Response bodies
+// are stored under Dir keyed by their sha256 digest, alongside a small
+// per-URL metadata file recording the ETag/Last-Modified and digest last
+// seen for that URL, so a later request can be sent with conditional
+// headers and a 304 from the server can be satisfied entirely from disk.
+type Cache struct {
+	// Dir is the directory cache entries are stored under. Its
+	// subdirectories are created on first use.
+	Dir string
+}
+
+// NewCache returns a Cache backed by dir.
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+type cacheEntry struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Digest       string `json:"digest"`
+}
+
+// Do performs req with client, consulting c first: if a previous response
+// for req.URL left behind an ETag or Last-Modified, Do sends it as
+// If-None-Match/If-Modified-Since, and a 304 from the server is resolved
+// straight from the cached body instead of being re-downloaded, with the
+// returned Response's status rewritten from "304 Not Modified" to
+// "200 (from cache)". A fresh 2xx response is buffered and stored in the
+// cache, keyed by its digest, for next time.
+func (c *Cache) Do(client *http.Client, req *http.Request) (*Response, error) {
+	entry, _ := c.load(req.URL.String())
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpResp.StatusCode == http.StatusNotModified && entry != nil {
+		httpResp.Body.Close()
+		return c.fromCache(httpResp, entry)
+	}
+
+	r := NewResponse(httpResp)
+	if r.StatusCode >= 200 && r.StatusCode < 300 {
+		if _, err := r.loadContent(); err != nil {
+			return nil, err
+		}
+		if err := c.store(req.URL.String(), r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// fromCache rebuilds a Response for a 304 using the body cached for entry,
+// rewriting the status to 200 to reflect that a full body is available.
+func (c *Cache) fromCache(httpResp *http.Response, entry *cacheEntry) (*Response, error) {
+	content, err := ioutil.ReadFile(c.objectPath(entry.Digest))
+	if err != nil {
+		return nil, err
+	}
+	httpResp.StatusCode = http.StatusOK
+	httpResp.Status = "200 (from cache)"
+	r := NewResponse(httpResp)
+	r.content = content
+	return r, nil
+}
+
+func (c *Cache) metaPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, "meta", hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *Cache) objectPath(digest string) string {
+	return filepath.Join(c.Dir, "objects", digest)
+}
+
+func (c *Cache) load(url string) (*cacheEntry, error) {
+	b, err := ioutil.ReadFile(c.metaPath(url))
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (c *Cache) store(url string, r *Response) error {
+	digest, err := r.Digest()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.Dir, "objects"), 0o755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(c.objectPath(digest.Hex), r.content, 0o644); err != nil {
+		return err
+	}
+
+	entry := cacheEntry{
+		ETag:         r.Header.Get("ETag"),
+		LastModified: r.Header.Get("Last-Modified"),
+		Digest:       digest.Hex,
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(c.Dir, "meta"), 0o755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.metaPath(url), b, 0o644)
+}
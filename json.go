@@ -0,0 +1,91 @@
+// Developed by Kaiser925 on 2021/2/2.
+// Lasted modified 2021/2/2.
+// Copyright (c) 2021.  All rights reserved
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requests4go
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSONError is returned by JSONInto when the response status is not 2xx:
+// it captures the decoded error payload and where decoding stopped, so
+// callers see the server's own error body instead of a raw unmarshal
+// error.
+type JSONError struct {
+	StatusCode int
+	// Body holds whatever JSON value the server sent back, typically an
+	// object describing the error.
+	Body interface{}
+	// Offset is the byte offset in the body where decoding stopped.
+	Offset int64
+}
+
+func (e *JSONError) Error() string {
+	return fmt.Sprintf("requests4go: unexpected status %d: %v", e.StatusCode, e.Body)
+}
+
+// JSONInto decodes the JSON body of r into a value of type T, streaming it
+// through Stream instead of buffering the whole response, and rejecting
+// unknown fields. If the status is not 2xx, it decodes the body into a
+// *JSONError instead, so non-2xx responses with JSON error payloads surface
+// structured diagnostics rather than a type mismatch.
+func JSONInto[T any](r *Response) (T, error) {
+	var v T
+
+	stream, err := r.Stream()
+	if err != nil {
+		return v, err
+	}
+	defer stream.Close()
+
+	dec := json.NewDecoder(stream)
+	dec.DisallowUnknownFields()
+
+	if r.StatusCode < 200 || r.StatusCode >= 300 {
+		var body interface{}
+		_ = dec.Decode(&body)
+		return v, &JSONError{
+			StatusCode: r.StatusCode,
+			Body:       body,
+			Offset:     dec.InputOffset(),
+		}
+	}
+
+	if err := dec.Decode(&v); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// NDJSON iterates newline-delimited JSON objects from the body of r,
+// streaming through Stream rather than buffering the whole response. fn is
+// called once per object with a decoder positioned to decode it; NDJSON
+// stops and returns fn's error as soon as it returns one. It's useful for
+// Elasticsearch _bulk/scroll-style responses and log APIs that return one
+// JSON object per line.
+func (r *Response) NDJSON(fn func(dec *json.Decoder) error) error {
+	stream, err := r.Stream()
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	dec := json.NewDecoder(stream)
+	for dec.More() {
+		if err := fn(dec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -18,20 +18,38 @@ import (
 	"compress/gzip"
 	"compress/zlib"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 
+	"github.com/andybalholm/brotli"
 	"github.com/bitly/go-simplejson"
 )
 
+// ErrBodyTooLarge is returned by the content-buffering methods of Response
+// when the body exceeds MaxBodyBytes.
+var ErrBodyTooLarge = errors.New("requests4go: response body too large")
+
 // Response is a wrapper of the http.Response.
 // It opens up new methods for http.Response.
 type Response struct {
 	*http.Response
 
+	// MaxBodyBytes, if non-zero, bounds how many bytes of the body Content,
+	// Text and JSON will buffer into memory and SaveContent will write to
+	// its file. Exceeding it returns
+	// ErrBodyTooLarge. It has no effect on Stream. Set it before the first
+	// call to one of those methods.
+	MaxBodyBytes int64
+
+	// Attempts records one entry per try made while obtaining this Response,
+	// oldest first, when it was produced by Do. It's nil for responses built
+	// any other way.
+	Attempts []AttemptInfo
+
 	// content stores the response data.
 	// It used to multiple read the content of body.
 	content []byte
@@ -40,8 +58,7 @@ type Response struct {
 // NewResponse returns new Response
 func NewResponse(resp *http.Response) *Response {
 	return &Response{
-		resp,
-		nil,
+		Response: resp,
 	}
 }
 
@@ -99,7 +116,10 @@ func (r *Response) SimpleJSON() (*simplejson.Json, error) {
 	return simplejson.NewJson(content)
 }
 
-// SaveContent reads body of response and saves response body to file.
+// SaveContent streams the body of response to file, without buffering it
+// into memory, so it is safe to use for large downloads. It honors
+// MaxBodyBytes the same way Content does, removing the partially written
+// file and returning ErrBodyTooLarge if the body exceeds it.
 func (r *Response) SaveContent(filename string) error {
 	f, err := os.Create(filename)
 	if err != nil {
@@ -107,18 +127,83 @@ func (r *Response) SaveContent(filename string) error {
 	}
 	defer f.Close()
 
-	content, err := r.loadContent()
+	stream, err := r.Stream()
 	if err != nil {
 		return err
 	}
+	defer stream.Close()
+
+	var reader io.Reader = stream
+	if r.MaxBodyBytes > 0 {
+		reader = io.LimitReader(stream, r.MaxBodyBytes+1)
+	}
 
-	_, err = f.Write(content)
+	n, err := io.Copy(f, reader)
 	if err != nil {
 		return err
 	}
+	if r.MaxBodyBytes > 0 && n > r.MaxBodyBytes {
+		f.Close()
+		os.Remove(filename)
+		return ErrBodyTooLarge
+	}
 	return nil
 }
 
+// ContentLimited reads at most n bytes of the body into memory, returning
+// ErrBodyTooLarge if the body is larger. It's a convenience for one-off
+// calls that don't want to set MaxBodyBytes on the Response themselves.
+func (r *Response) ContentLimited(n int64) ([]byte, error) {
+	if r.content != nil && int64(len(r.content)) > n {
+		return nil, ErrBodyTooLarge
+	}
+	r.MaxBodyBytes = n
+	return r.Content()
+}
+
+// Stream returns a reader over the body of response, transparently
+// decoding Content-Encoding (gzip, deflate or br) without buffering it into
+// memory. Closing the returned reader also closes the underlying
+// http.Response body. If Content, Text, JSON or another buffering method
+// has already been called on r, Stream reads from the buffered content
+// instead.
+func (r *Response) Stream() (io.ReadCloser, error) {
+	if r.content != nil {
+		return ioutil.NopCloser(bytes.NewReader(r.content)), nil
+	}
+
+	switch r.Header.Get("Content-Encoding") {
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &decodedBody{gz, r.Body}, nil
+	case "deflate":
+		zl, err := zlib.NewReader(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &decodedBody{zl, r.Body}, nil
+	case "br":
+		return &decodedBody{brotli.NewReader(r.Body), r.Body}, nil
+	default:
+		return r.Body, nil
+	}
+}
+
+// decodedBody pairs a decoding reader with the underlying response body, so
+// that closing it also releases the network connection; gzip.Reader,
+// zlib's reader and brotli.Reader don't close the stream they wrap.
+type decodedBody struct {
+	io.Reader
+	orig io.Closer
+}
+
+func (d *decodedBody) Close() error {
+	return d.orig.Close()
+}
+
 // JSON reads body of response and unmarshal the response content to v.
 func (r *Response) JSON(v interface{}) error {
 	content, err := r.loadContent()
@@ -132,29 +217,26 @@ func (r *Response) loadContent() ([]byte, error) {
 	if r.content != nil {
 		return r.content, nil
 	}
-	var reader io.ReadCloser
 
-	defer func() {
-		reader.Close()
-	}()
+	stream, err := r.Stream()
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
 
-	var err error
-	switch r.Header.Get("Content-Encoding") {
-	case "gzip":
-		if reader, err = gzip.NewReader(r.Body); err != nil {
-			return nil, err
-		}
-	case "deflate":
-		if reader, err = zlib.NewReader(r.Body); err != nil {
-			return nil, err
-		}
-	default:
-		reader = r.Body
+	var reader io.Reader = stream
+	if r.MaxBodyBytes > 0 {
+		reader = io.LimitReader(stream, r.MaxBodyBytes+1)
 	}
+
 	content, err := ioutil.ReadAll(reader)
 	if err != nil && err != io.EOF {
 		return nil, err
 	}
+	if r.MaxBodyBytes > 0 && int64(len(content)) > r.MaxBodyBytes {
+		return nil, ErrBodyTooLarge
+	}
+
 	r.content = content
 	return content, nil
 }
@@ -0,0 +1,206 @@
+// Developed by Kaiser925 on 2021/2/2.
+// Lasted modified 2021/2/2.
+// Copyright (c) 2021.  All rights reserved
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requests4go
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls whether, how often and how long Do retries a request.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first
+	// one. Zero means DefaultRetryPolicy's value is used.
+	MaxAttempts int
+
+	// Backoff spaces out attempts when the response carries no Retry-After
+	// header.
+	Backoff Backoff
+
+	// Classifier decides whether a given response/error pair should be
+	// retried. It defaults to DefaultClassifier.
+	Classifier func(*http.Response, error) bool
+}
+
+// DefaultRetryPolicy returns the policy Do uses when none is given:
+// DefaultBackoff()'s attempt count and delays, retrying on network errors
+// and DefaultClassifier's status codes.
+func DefaultRetryPolicy() RetryPolicy {
+	b := DefaultBackoff()
+	return RetryPolicy{
+		MaxAttempts: b.MaxAttempts,
+		Backoff:     b,
+		Classifier:  DefaultClassifier,
+	}
+}
+
+// DefaultClassifier reports whether a request should be retried: on every
+// network error, and on 429, 502, 503 and 504 responses.
+func DefaultClassifier(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// AttemptInfo records the outcome of a single attempt made by Do.
+type AttemptInfo struct {
+	// StatusCode is zero if the attempt failed before a response arrived.
+	StatusCode int
+	Elapsed    time.Duration
+	Err        error
+}
+
+// Do executes req with client, retrying according to policy. A zero
+// RetryPolicy is replaced with DefaultRetryPolicy. The request body, if
+// any, is buffered so it can be replayed on every attempt; set req.GetBody
+// yourself beforehand if you'd rather control how it's replayed. Retries
+// honor a Retry-After header on the preceding response, falling back to
+// policy.Backoff otherwise. The returned Response's Attempts field records
+// one entry per try, in order, so callers can diagnose flaky endpoints.
+func Do(client *http.Client, req *http.Request, policy RetryPolicy) (*Response, error) {
+	def := DefaultRetryPolicy()
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = def.MaxAttempts
+	}
+	if policy.Backoff == (Backoff{}) {
+		policy.Backoff = def.Backoff
+	}
+	if policy.Classifier == nil {
+		policy.Classifier = def.Classifier
+	}
+	if err := ensureGetBody(req); err != nil {
+		return nil, err
+	}
+
+	var attempts []AttemptInfo
+	var lastResp *http.Response
+	var lastErr error
+
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := rewind(req); err != nil {
+				return nil, err
+			}
+			time.Sleep(retryDelay(lastResp, policy.Backoff, attempt))
+		}
+
+		start := time.Now()
+		resp, err := client.Do(req)
+		info := AttemptInfo{Elapsed: time.Since(start), Err: err}
+		if resp != nil {
+			info.StatusCode = resp.StatusCode
+		}
+		attempts = append(attempts, info)
+
+		if !policy.Classifier(resp, err) {
+			if err != nil {
+				return nil, err
+			}
+			if lastResp != nil {
+				lastResp.Body.Close()
+			}
+			r := NewResponse(resp)
+			r.Attempts = attempts
+			return r, nil
+		}
+
+		// This attempt is being retried, so the previous attempt's response
+		// is superseded: close it now. This attempt's response stays open,
+		// since if it turns out to be the last one the caller reads its body.
+		if lastResp != nil {
+			lastResp.Body.Close()
+		}
+		lastResp, lastErr = resp, err
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	r := NewResponse(lastResp)
+	r.Attempts = attempts
+	return r, nil
+}
+
+// rewind replays req's body ahead of a retry, using req.GetBody.
+func rewind(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+// ensureGetBody makes sure req.GetBody is set, buffering req.Body once if
+// necessary, so rewind can replay it on every retry.
+func ensureGetBody(req *http.Request) error {
+	if req.GetBody != nil || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
+
+// retryDelay returns how long to wait before the given attempt, preferring
+// a Retry-After header on resp when present.
+func retryDelay(resp *http.Response, backoff Backoff, attempt int) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+	return backoff.Delay(attempt - 1)
+}
+
+// retryAfter parses a Retry-After header, which per RFC 7231 is either a
+// number of delta-seconds or an HTTP-date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,80 @@
+// Developed by Kaiser925 on 2021/2/2.
+// Lasted modified 2021/2/2.
+// Copyright (c) 2021.  All rights reserved
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requests4go
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubRoundTripper returns one canned response per call, in order.
+type stubRoundTripper struct {
+	responses []*http.Response
+	calls     int
+}
+
+func (s *stubRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp := s.responses[s.calls]
+	s.calls++
+	return resp, nil
+}
+
+func newStatusResponse(code int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Status:     http.StatusText(code),
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestDo_ExhaustedRetriesKeepsBodyReadable(t *testing.T) {
+	rt := &stubRoundTripper{
+		responses: []*http.Response{
+			newStatusResponse(http.StatusServiceUnavailable, "attempt1"),
+			newStatusResponse(http.StatusServiceUnavailable, "attempt2"),
+		},
+	}
+	client := &http.Client{Transport: rt}
+	req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	resp, err := Do(client, req, RetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     Backoff{Base: time.Millisecond, Factor: 1, Cap: time.Millisecond, MaxAttempts: 2},
+	})
+	if err != nil {
+		t.Fatalf("Do returned error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected final status %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	if len(resp.Attempts) != 2 {
+		t.Fatalf("expected 2 recorded attempts, got %d", len(resp.Attempts))
+	}
+
+	content, err := resp.Text()
+	if err != nil {
+		t.Fatalf("Text() on exhausted response: %v", err)
+	}
+	if content != "attempt2" {
+		t.Fatalf("expected body of the last attempt %q, got %q", "attempt2", content)
+	}
+}
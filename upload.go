@@ -0,0 +1,310 @@
+// Developed by Kaiser925 on 2021/2/2.
+// Lasted modified 2021/2/2.
+// Copyright (c) 2021.  All rights reserved
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requests4go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSessionNotEstablished is returned when the server's response to the
+// session-creation POST doesn't contain a Location header to upload to.
+var ErrSessionNotEstablished = errors.New("requests4go: upload response has no Location header")
+
+const defaultChunkSize = 8 << 20 // 8MiB
+
+// ResumableUploadOption configures a ResumableUpload call.
+type ResumableUploadOption func(*resumableUpload)
+
+// WithChunkSize sets the number of bytes sent per PUT request. It defaults
+// to 8MiB.
+func WithChunkSize(n int64) ResumableUploadOption {
+	return func(u *resumableUpload) { u.chunkSize = n }
+}
+
+// WithUploadBackoff overrides the retry backoff used between failed chunk
+// uploads. It defaults to DefaultBackoff().
+func WithUploadBackoff(b Backoff) ResumableUploadOption {
+	return func(u *resumableUpload) { u.backoff = b }
+}
+
+// WithSessionURL resumes an upload using a session URL obtained from the
+// Location header of a previous ResumableUpload call, instead of starting a
+// new session.
+func WithSessionURL(url string) ResumableUploadOption {
+	return func(u *resumableUpload) { u.sessionURL = url }
+}
+
+// WithUploadHeader sets a header sent on the initial session-creation
+// request.
+func WithUploadHeader(key, value string) ResumableUploadOption {
+	return func(u *resumableUpload) {
+		if u.header == nil {
+			u.header = http.Header{}
+		}
+		u.header.Add(key, value)
+	}
+}
+
+// WithUploadClient overrides the *http.Client used to issue requests. It
+// defaults to http.DefaultClient.
+func WithUploadClient(client *http.Client) ResumableUploadOption {
+	return func(u *resumableUpload) { u.client = client }
+}
+
+type resumableUpload struct {
+	client     *http.Client
+	chunkSize  int64
+	backoff    Backoff
+	sessionURL string
+	header     http.Header
+}
+
+// ResumableUpload uploads the content read from r, which has the given total
+// size in bytes, to url using a resumable upload session modeled on Google's
+// media upload protocol: an initial POST creates a session whose URL is
+// returned in the Location header, then the body is PUT in chunkSize pieces
+// with a "Content-Range: bytes A-B/TOTAL" header on each one. If a request
+// fails with a network error or a 5xx/429 response, ResumableUpload backs
+// off and resends the same request, up to the backoff's MaxAttempts; it
+// does not query the session status between those attempts. A zero-length
+// "Content-Range: bytes */TOTAL" status PUT is sent only to find the last
+// byte the server committed when resuming via WithSessionURL, and to fetch
+// the final response once the server reports every byte committed.
+//
+// r must implement io.ReaderAt, not just io.Reader: a chunk may need to be
+// re-read from the same offset after a failed attempt or a server-reported
+// resume, and io.ReaderAt is what lets ResumableUpload do that without
+// buffering the whole upload in memory. Callers that only have a plain
+// io.Reader (e.g. a network stream) need to wrap it in something that
+// supports ReadAt, such as an in-memory buffer or a spooled temp file,
+// before calling ResumableUpload.
+//
+// Pass WithSessionURL to resume an upload on an existing session URL. The
+// session URL created by a call is not returned by it: the Response that
+// ResumableUpload returns is the server's reply to the final PUT, and no
+// Response is returned when the upload fails.
+func ResumableUpload(ctx context.Context, url string, r io.ReaderAt, size int64, opts ...ResumableUploadOption) (*Response, error) {
+	u := &resumableUpload{
+		client:    http.DefaultClient,
+		chunkSize: defaultChunkSize,
+		backoff:   DefaultBackoff(),
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	sessionURL := u.sessionURL
+	offset := int64(0)
+
+	if sessionURL == "" {
+		var err error
+		if sessionURL, err = u.createSession(ctx, url, size); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if offset, _, err = u.queryOffset(ctx, sessionURL, size); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		resp, committed, err := u.putChunk(ctx, sessionURL, r, offset, size)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusPermanentRedirect {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		if committed <= offset {
+			return nil, fmt.Errorf("requests4go: upload stalled at offset %d/%d, server did not acknowledge progress", offset, size)
+		}
+		offset = committed
+	}
+}
+
+// createSession POSTs to url to obtain a resumable session URL from the
+// Location header of the response.
+func (u *resumableUpload) createSession(ctx context.Context, url string, size int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, vs := range u.header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	req.ContentLength = 0
+
+	resp, err := u.doWithRetry(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", ErrSessionNotEstablished
+	}
+	return location, nil
+}
+
+// statusResponse issues a zero-length "bytes */size" PUT to discover the
+// server's upload status for sessionURL, returning the raw response along
+// with the number of bytes it has committed so far. A non-308 response
+// means the upload is already complete.
+func (u *resumableUpload) statusResponse(ctx context.Context, sessionURL string, size int64) (*Response, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	req.ContentLength = 0
+
+	resp, err := u.doWithRetry(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusPermanentRedirect {
+		return resp, size, nil
+	}
+	return resp, committedOffset(resp.Header.Get("Range")), nil
+}
+
+// committedOffset parses the next byte to send out of a "Range: bytes=0-N"
+// header, returning 0 if it's missing or malformed.
+func committedOffset(rangeHeader string) int64 {
+	if rangeHeader == "" {
+		return 0
+	}
+	_, last, ok := strings.Cut(rangeHeader, "-")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n + 1
+}
+
+// queryOffset discovers the last byte the server has committed for
+// sessionURL, reporting done if the upload has already finished.
+func (u *resumableUpload) queryOffset(ctx context.Context, sessionURL string, size int64) (offset int64, done bool, err error) {
+	resp, offset, err := u.statusResponse(ctx, sessionURL, size)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+	return offset, resp.StatusCode != http.StatusPermanentRedirect, nil
+}
+
+// putChunk sends the next chunk of r starting at offset, returning the
+// response and, if the server reports 308 Resume Incomplete, the offset it
+// wants the next chunk to start at. If offset has already reached size
+// (e.g. a resumed session whose upload had already finished), it instead
+// re-queries the session status to obtain the terminal response.
+func (u *resumableUpload) putChunk(ctx context.Context, sessionURL string, r io.ReaderAt, offset, size int64) (*Response, int64, error) {
+	if offset >= size {
+		resp, _, err := u.statusResponse(ctx, sessionURL, size)
+		return resp, size, err
+	}
+	n := u.chunkSize
+	if offset+n > size {
+		n = size - offset
+	}
+	return u.putRange(ctx, sessionURL, r, offset, n, size)
+}
+
+func (u *resumableUpload) putRange(ctx context.Context, sessionURL string, r io.ReaderAt, offset, n, size int64) (*Response, int64, error) {
+	buf := make([]byte, n)
+	if n > 0 {
+		if _, err := r.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURL, bytes.NewReader(buf))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.ContentLength = n
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+n-1, size))
+
+	resp, err := u.doWithRetry(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if resp.StatusCode == http.StatusPermanentRedirect {
+		return resp, committedOffset(resp.Header.Get("Range")), nil
+	}
+	return resp, size, nil
+}
+
+// doWithRetry executes req, retrying on network errors and 5xx/429
+// responses according to u.backoff. 308 Resume Incomplete is not an error
+// and is returned as-is.
+func (u *resumableUpload) doWithRetry(req *http.Request) (*Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < u.backoff.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(u.backoff.Delay(attempt - 1)):
+			}
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		httpResp, err := u.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if isRetryableStatus(httpResp.StatusCode) {
+			httpResp.Body.Close()
+			lastErr = fmt.Errorf("requests4go: upload attempt %d failed with status %s", attempt+1, httpResp.Status)
+			continue
+		}
+		return NewResponse(httpResp), nil
+	}
+	return nil, lastErr
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code <= 599)
+}
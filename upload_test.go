@@ -0,0 +1,65 @@
+// Developed by Kaiser925 on 2021/2/2.
+// Lasted modified 2021/2/2.
+// Copyright (c) 2021.  All rights reserved
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requests4go
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// staticRangeRoundTripper always reports the same committed range, so a
+// caller that blindly trusts it would retry the same chunk forever.
+type staticRangeRoundTripper struct{}
+
+func (staticRangeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	h := http.Header{}
+	h.Set("Range", "bytes=0-0")
+	return &http.Response{
+		StatusCode: http.StatusPermanentRedirect,
+		Status:     "308 Resume Incomplete",
+		Header:     h,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+func TestResumableUpload_StalledOffsetErrors(t *testing.T) {
+	client := &http.Client{Transport: staticRangeRoundTripper{}}
+	data := bytes.NewReader(make([]byte, 32))
+
+	done := make(chan struct{})
+	var err error
+	go func() {
+		_, err = ResumableUpload(context.Background(), "http://example.com/upload", data, 32,
+			WithUploadClient(client),
+			WithSessionURL("http://example.com/upload/session"),
+			WithChunkSize(8),
+		)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ResumableUpload hung instead of erroring on a stalled offset")
+	}
+
+	if err == nil {
+		t.Fatal("expected an error when the server never advances the upload offset, got nil")
+	}
+}